@@ -0,0 +1,79 @@
+package helm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+// dataTemplate renders a chart client-side, without installing it, using
+// the same repository/OCI/TLS plumbing as resourceRelease.
+func dataTemplate() *schema.Resource {
+	s := releaseSchema(true)
+	s["manifest"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "The rendered manifest as YAML.",
+	}
+
+	return &schema.Resource{
+		ReadContext: dataTemplateRead,
+		Schema:      s,
+	}
+}
+
+func dataTemplateRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	m := meta.(*Meta)
+	namespace := d.Get("namespace").(string)
+
+	actionConfig, err := m.GetHelmConfiguration(d, namespace)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := OCIRegistryLogin(actionConfig, d, m); err != nil {
+		return diag.FromErr(err)
+	}
+
+	cpo, cleanup, err := buildChartPathOptions(d)
+	defer cleanup()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	chartPath, err := cpo.LocateChart(d.Get("chart").(string), m.Settings)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("could not locate chart: %v", err))
+	}
+
+	chrt, err := loader.Load(chartPath)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("could not load chart: %v", err))
+	}
+
+	vals, err := resolveReleaseValues(m, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	install := action.NewInstall(actionConfig)
+	install.ChartPathOptions = *cpo
+	install.Namespace = namespace
+	install.ReleaseName = d.Get("name").(string)
+	install.DryRun = true
+	install.ClientOnly = true
+
+	rel, err := install.Run(chrt, vals)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("could not render chart: %v", err))
+	}
+
+	d.SetId(rel.Name)
+	d.Set("manifest", rel.Manifest)
+	return nil
+}
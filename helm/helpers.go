@@ -3,12 +3,15 @@ package helm
 import (
 	"fmt"
 	"log"
+	"net/http"
 	"net/url"
-	"sync"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
 )
 
 var k8sPrefix = "kubernetes.0."
@@ -33,7 +36,22 @@ func (m *Meta) GetHelmConfiguration(d dataGetter, namespace string) (*action.Con
 		return nil, err
 	}
 
-	if err := actionConfig.Init(kc, namespace, m.HelmDriver, debug); err != nil {
+	if strings.EqualFold(m.HelmDriver, driver.SQLDriverName) {
+		// The SQL driver is configured explicitly via the provider's
+		// helm_driver_sql block rather than relying on Helm's own
+		// HELM_DRIVER_SQL_CONNECTION_STRING environment variable, so we
+		// build it ourselves and hand it to actionConfig instead of
+		// delegating driver selection to Init.
+		if err := actionConfig.Init(kc, namespace, "memory", debug); err != nil {
+			return nil, err
+		}
+
+		sqlDriver, err := driver.NewSQL(m.HelmDriverSQLConnectionString, debug, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("could not create SQL storage driver: %v", err)
+		}
+		actionConfig.Releases = storage.Init(sqlDriver)
+	} else if err := actionConfig.Init(kc, namespace, m.HelmDriver, debug); err != nil {
 		return nil, err
 	}
 
@@ -48,19 +66,8 @@ type dataGetter interface {
 	GetOkExists(string) (interface{}, bool)
 }
 
-// loggedInOCIRegistries is used to make sure we log into a registry only
-// once if it is used across multiple resources concurrently
-var loggedInOCIRegistries map[string]string = map[string]string{}
-var OCILoginMutex sync.Mutex
-
 // OCIRegistryLogin creates an OCI registry client and logs into the registry if needed
-func OCIRegistryLogin(actionConfig *action.Configuration, d dataGetter) error {
-	registryClient, err := registry.NewClient()
-	if err != nil {
-		return fmt.Errorf("could not create OCI registry client: %v", err)
-	}
-	actionConfig.RegistryClient = registryClient
-
+func OCIRegistryLogin(actionConfig *action.Configuration, d dataGetter, m *Meta) error {
 	// log in to the registry if neccessary
 	repository := d.Get("repository").(string)
 	chartName := d.Get("chart").(string)
@@ -70,32 +77,68 @@ func OCIRegistryLogin(actionConfig *action.Configuration, d dataGetter) error {
 	} else if registry.IsOCI(chartName) {
 		ociURL = chartName
 	}
-	if ociURL == "" {
-		return nil
-	}
 
-	username := d.Get("repository_username").(string)
-	password := d.Get("repository_password").(string)
-	if username != "" && password != "" {
+	var host string
+	if ociURL != "" {
 		u, err := url.Parse(ociURL)
 		if err != nil {
 			return fmt.Errorf("could not parse OCI registry URL: %v", err)
 		}
+		host = u.Host
+	}
 
-		OCILoginMutex.Lock()
-		defer OCILoginMutex.Unlock()
-		if _, ok := loggedInOCIRegistries[u.Host]; ok {
-			debug("[INFO] Already logged into OCI registry %q", u.Host)
-			return nil
+	tlsOpts := getRegistryTLSOptions(d)
+	if host != "" {
+		if cred, ok := m.RegistryLogins.credentialsFor(host); ok && cred.CAFile != "" {
+			tlsOpts.CAFile = cred.CAFile
 		}
-		err = registryClient.Login(u.Host,
-			registry.LoginOptBasicAuth(username, password))
+	}
+
+	clientOpts := []registry.ClientOption{}
+	if !tlsOpts.Empty() {
+		tlsConfig, err := tlsOpts.tlsConfig()
 		if err != nil {
-			return fmt.Errorf("could not login to OCI registry %q: %v", u.Host, err)
+			return fmt.Errorf("could not configure TLS for OCI registry: %v", err)
 		}
-		loggedInOCIRegistries[u.Host] = ""
-		debug("[INFO] Logged into OCI registry")
+		clientOpts = append(clientOpts, registry.ClientOptHTTPClient(&http.Client{
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		}))
+	}
+
+	registryClient, err := registry.NewClient(clientOpts...)
+	if err != nil {
+		return fmt.Errorf("could not create OCI registry client: %v", err)
+	}
+	actionConfig.RegistryClient = registryClient
+
+	if host == "" {
+		return nil
+	}
+
+	if m.RegistryLogins.isLoggedIn(host) {
+		debug("[INFO] Already logged into OCI registry %q", host)
+		return nil
+	}
+
+	username := d.Get("repository_username").(string)
+	password := d.Get("repository_password").(string)
+	if cred, ok := m.RegistryLogins.credentialsFor(host); ok {
+		username, password = cred.Username, cred.Password
+	}
+	if username == "" || password == "" {
+		return nil
+	}
+
+	if err := registryClient.Login(host, registry.LoginOptBasicAuth(username, password)); err != nil {
+		// A host is only marked as logged in after a successful Login, so
+		// a failed attempt is tried again on the next call. A host that is
+		// already marked is not logged into again, even if the credentials
+		// it logged in with have since expired.
+		m.RegistryLogins.Invalidate(host)
+		return fmt.Errorf("could not login to OCI registry %q: %v", host, err)
 	}
+	m.RegistryLogins.markLoggedIn(host)
+	debug("[INFO] Logged into OCI registry")
 
 	return nil
 }
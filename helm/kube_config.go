@@ -0,0 +1,173 @@
+package helm
+
+import (
+	"fmt"
+
+	"github.com/mitchellh/go-homedir"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// kubeConfig adapts the provider's "kubernetes" block into the
+// genericclioptions.RESTClientGetter that action.Configuration.Init needs
+// in order to talk to the cluster.
+type kubeConfig struct {
+	restConfig *rest.Config
+}
+
+func (k *kubeConfig) ToRESTConfig() (*rest.Config, error) {
+	return k.restConfig, nil
+}
+
+func (k *kubeConfig) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	dc, err := discovery.NewDiscoveryClientForConfig(k.restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return memory.NewMemCacheClient(dc), nil
+}
+
+func (k *kubeConfig) ToRESTMapper() (meta.RESTMapper, error) {
+	dc, err := k.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(dc)
+	return restmapper.NewShortcutExpander(mapper, dc), nil
+}
+
+func (k *kubeConfig) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	return clientcmd.NewDefaultClientConfig(*clientcmdapi.NewConfig(), &clientcmd.ConfigOverrides{})
+}
+
+// newKubeConfig builds the genericclioptions.RESTClientGetter used to
+// connect to the cluster from the provider's "kubernetes" block, including
+// the --as/--as-group impersonation identity so that release create,
+// update and delete all run as the impersonated user rather than just the
+// EnvSettings-consuming code paths.
+func newKubeConfig(d dataGetter, namespace *string) (genericclioptions.RESTClientGetter, error) {
+	overrides := &clientcmd.ConfigOverrides{}
+	loader := &clientcmd.ClientConfigLoadingRules{}
+
+	configPaths := []string{}
+	if v, ok := k8sGetOk(d, "config_path"); ok && v.(string) != "" {
+		configPaths = []string{v.(string)}
+	} else if v, ok := k8sGetOk(d, "config_paths"); ok {
+		for _, p := range v.([]interface{}) {
+			configPaths = append(configPaths, p.(string))
+		}
+	}
+
+	if len(configPaths) > 0 {
+		expanded := make([]string, 0, len(configPaths))
+		for _, p := range configPaths {
+			path, err := homedir.Expand(p)
+			if err != nil {
+				return nil, fmt.Errorf("could not expand kubeconfig path %q: %v", p, err)
+			}
+			expanded = append(expanded, path)
+		}
+
+		if len(expanded) == 1 {
+			loader.ExplicitPath = expanded[0]
+		} else {
+			loader.Precedence = expanded
+		}
+
+		if v, ok := k8sGetOk(d, "config_context"); ok {
+			overrides.CurrentContext = v.(string)
+		}
+		if v, ok := k8sGetOk(d, "config_context_auth_info"); ok {
+			overrides.Context.AuthInfo = v.(string)
+		}
+		if v, ok := k8sGetOk(d, "config_context_cluster"); ok {
+			overrides.Context.Cluster = v.(string)
+		}
+	}
+
+	if v, ok := k8sGetOk(d, "insecure"); ok {
+		overrides.ClusterInfo.InsecureSkipTLSVerify = v.(bool)
+	}
+	if v, ok := k8sGetOk(d, "cluster_ca_certificate"); ok {
+		overrides.ClusterInfo.CertificateAuthorityData = []byte(v.(string))
+	}
+	if v, ok := k8sGetOk(d, "host"); ok {
+		overrides.ClusterInfo.Server = v.(string)
+	}
+	if v, ok := k8sGetOk(d, "username"); ok {
+		overrides.AuthInfo.Username = v.(string)
+	}
+	if v, ok := k8sGetOk(d, "password"); ok {
+		overrides.AuthInfo.Password = v.(string)
+	}
+	if v, ok := k8sGetOk(d, "client_certificate"); ok {
+		overrides.AuthInfo.ClientCertificateData = []byte(v.(string))
+	}
+	if v, ok := k8sGetOk(d, "client_key"); ok {
+		overrides.AuthInfo.ClientKeyData = []byte(v.(string))
+	}
+	if v, ok := k8sGetOk(d, "token"); ok {
+		overrides.AuthInfo.Token = v.(string)
+	}
+	if v, ok := k8sGetOk(d, "proxy_url"); ok {
+		overrides.ClusterDefaults.ProxyURL = v.(string)
+	}
+
+	// Impersonation: --as/--as-group. This is what release create, update
+	// and delete actually authenticate with, as opposed to
+	// settings.KubeAsUser/KubeAsGroup which only Helm's own
+	// EnvSettings-consuming code paths (e.g. plugin invocations) see.
+	if v, ok := k8sGetOk(d, "kube_as_user"); ok {
+		overrides.AuthInfo.Impersonate = v.(string)
+	}
+	if v, ok := k8sGetOk(d, "kube_as_group"); ok {
+		overrides.AuthInfo.ImpersonateGroups = expandStringSlice(v.([]interface{}))
+	}
+
+	if v, ok := k8sGetOk(d, "exec"); ok {
+		if spec, ok := expandExecConfig(v); ok {
+			overrides.AuthInfo.Exec = spec
+		}
+	}
+
+	cc := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loader, overrides)
+	restConfig, err := cc.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("could not build Kubernetes client config: %v", err)
+	}
+
+	if namespace != nil && *namespace == "" {
+		if ns, _, err := cc.Namespace(); err == nil {
+			*namespace = ns
+		}
+	}
+
+	return &kubeConfig{restConfig: restConfig}, nil
+}
+
+// expandExecConfig converts the "exec" block into a clientcmd ExecConfig.
+func expandExecConfig(raw interface{}) (*clientcmdapi.ExecConfig, bool) {
+	list := raw.([]interface{})
+	if len(list) == 0 || list[0] == nil {
+		return nil, false
+	}
+	spec := list[0].(map[string]interface{})
+
+	exec := &clientcmdapi.ExecConfig{
+		APIVersion: spec["api_version"].(string),
+		Command:    spec["command"].(string),
+		Args:       expandStringSlice(spec["args"].([]interface{})),
+	}
+
+	for k, v := range spec["env"].(map[string]interface{}) {
+		exec.Env = append(exec.Env, clientcmdapi.ExecEnvVar{Name: k, Value: v.(string)})
+	}
+
+	return exec, true
+}
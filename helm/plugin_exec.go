@@ -0,0 +1,51 @@
+package helm
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+
+	"helm.sh/helm/v3/pkg/plugin"
+)
+
+// RunPlugin invokes the named, already-installed Helm plugin with input on
+// its stdin and returns whatever it wrote to stdout. This is the mechanism
+// that backs a helm_release's post_renderer/values_decryptor: both refer to
+// a plugin by name, and the release apply path pipes the rendered manifest
+// or raw values through here before handing the result back to Helm.
+func RunPlugin(m *Meta, name string, input []byte) ([]byte, error) {
+	plugins, err := plugin.FindPlugins(m.Settings.PluginsDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("could not list installed plugins: %v", err)
+	}
+
+	var p *plugin.Plugin
+	for _, candidate := range plugins {
+		if candidate.Metadata.Name == name {
+			p = candidate
+			break
+		}
+	}
+	if p == nil {
+		return nil, fmt.Errorf("plugin %q is not installed in %s", name, m.Settings.PluginsDirectory)
+	}
+
+	prog, args, err := p.PrepareCommand(nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve command for plugin %q: %v", name, err)
+	}
+
+	cmd := exec.Command(prog, args...)
+	cmd.Dir = p.Dir
+	cmd.Stdin = bytes.NewReader(input)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("plugin %q exited with an error: %v: %s", name, err, stderr.String())
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -27,6 +27,15 @@ type Meta struct {
 	Settings   *cli.EnvSettings
 	HelmDriver string
 
+	// HelmDriverSQLConnectionString and HelmDriverSQLDialect are only used
+	// when HelmDriver is "sql".
+	HelmDriverSQLConnectionString string
+	HelmDriverSQLDialect          string
+
+	// RegistryLogins caches OCI registry logins resolved from the
+	// provider's "registry" blocks, keyed by registry host.
+	RegistryLogins *registryLoginCache
+
 	// Used to lock some operations
 	sync.Mutex
 
@@ -104,6 +113,69 @@ func Provider() *schema.Provider {
 				Description: "Kubernetes configuration.",
 				Elem:        kubernetesResource(),
 			},
+			"registry": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Configuration for a registry used for OCI registry based chart repositories.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"url": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "OCI URL in form oci://registry domain without the repository",
+						},
+						"username": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							Description: "The username to use to authenticate with the registry",
+						},
+						"password": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							Sensitive:   true,
+							Description: "The password to use to authenticate with the registry",
+						},
+						"ca_file": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							Description: "The CA file to use to authenticate with the registry, as inline PEM data or a path to a file containing it",
+						},
+						"docker_config_json": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "",
+							Sensitive:   true,
+							Description: "A dockerconfigjson document (e.g. from a Kubernetes docker-registry secret) to source per-host credentials from",
+						},
+					},
+				},
+			},
+			"helm_driver_sql": {
+				Type:        schema.TypeList,
+				MaxItems:    1,
+				Optional:    true,
+				Description: "SQL storage backend configuration, used when helm_driver is set to \"sql\".",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"connection_string": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Sensitive:   true,
+							DefaultFunc: schema.EnvDefaultFunc("HELM_DRIVER_SQL_CONNECTION_STRING", ""),
+							Description: "The connection string to use to connect to the SQL storage backend.",
+						},
+						"dialect": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "postgres",
+							Description: "The SQL dialect to use. Only \"postgres\" is currently supported upstream.",
+						},
+					},
+				},
+			},
 			"experiments": {
 				Type:        schema.TypeList,
 				MaxItems:    1,
@@ -132,6 +204,7 @@ func Provider() *schema.Provider {
 		},
 		ResourcesMap: map[string]*schema.Resource{
 			"helm_release": resourceRelease(),
+			"helm_plugin":  resourcePlugin(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
 			"helm_template": dataTemplate(),
@@ -224,6 +297,18 @@ func kubernetesResource() *schema.Resource {
 				DefaultFunc: schema.EnvDefaultFunc("KUBE_TOKEN", ""),
 				Description: "Token to authenticate an service account",
 			},
+			"kube_as_user": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KUBE_AS_USER", ""),
+				Description: "Username to impersonate for the operation",
+			},
+			"kube_as_group": {
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Optional:    true,
+				Description: "Group names to impersonate for the operation",
+			},
 			"proxy_url": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -302,12 +387,31 @@ func providerConfigure(d *schema.ResourceData, terraformVersion string) (interfa
 		settings.RepositoryCache = v.(string)
 	}
 
+	if v, ok := d.GetOk("kubernetes.0.kube_as_user"); ok {
+		settings.KubeAsUser = v.(string)
+	}
+
+	if v, ok := d.GetOk("kubernetes.0.kube_as_group"); ok {
+		settings.KubeAsGroup = expandStringSlice(v.([]interface{}))
+	}
+
 	m.Settings = settings
 
 	if v, ok := d.GetOk("helm_driver"); ok {
 		m.HelmDriver = v.(string)
 	}
 
+	if strings.EqualFold(m.HelmDriver, driver.SQLDriverName) {
+		m.HelmDriverSQLConnectionString = d.Get("helm_driver_sql.0.connection_string").(string)
+		m.HelmDriverSQLDialect = d.Get("helm_driver_sql.0.dialect").(string)
+	}
+
+	registryCreds, err := buildRegistryCredentials(d.Get("registry").([]interface{}))
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+	m.RegistryLogins = newRegistryLoginCache(registryCreds)
+
 	return m, nil
 }
 
@@ -0,0 +1,177 @@
+package helm
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// registryCredential holds the login material resolved for a single OCI
+// registry host, either from a provider-level "registry" block or from a
+// docker_config_json blob attached to one.
+type registryCredential struct {
+	Username string
+	Password string
+	CAFile   string
+}
+
+// dockerConfigJSON mirrors the subset of ~/.docker/config.json (and
+// Kubernetes dockerconfigjson secrets) that we care about: per-host basic
+// auth credentials, optionally base64-encoded as "user:password" in Auth.
+type dockerConfigJSON struct {
+	Auths map[string]struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Auth     string `json:"auth"`
+	} `json:"auths"`
+}
+
+// buildRegistryCredentials expands the provider's "registry" blocks into a
+// map of host -> registryCredential, parsing docker_config_json the same
+// way kubelet does to pull out per-host creds.
+func buildRegistryCredentials(registries []interface{}) (map[string]registryCredential, error) {
+	creds := map[string]registryCredential{}
+
+	for _, raw := range registries {
+		r := raw.(map[string]interface{})
+
+		rawURL := r["url"].(string)
+		cred := registryCredential{
+			Username: r["username"].(string),
+			Password: r["password"].(string),
+			CAFile:   r["ca_file"].(string),
+		}
+
+		if rawURL != "" {
+			host, err := registryHost(rawURL)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse registry url %q: %v", rawURL, err)
+			}
+			creds[host] = cred
+		}
+
+		dockerConfigJSON := r["docker_config_json"].(string)
+		if dockerConfigJSON == "" {
+			continue
+		}
+
+		hostCreds, err := parseDockerConfigJSON(dockerConfigJSON)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse docker_config_json for registry %q: %v", rawURL, err)
+		}
+		for host, hostCred := range hostCreds {
+			hostCred.CAFile = cred.CAFile
+			creds[host] = hostCred
+		}
+	}
+
+	return creds, nil
+}
+
+// registryHost extracts the host portion of a registry URL, accepting both
+// a bare hostname (as used by dockerconfigjson) and an oci:// URL (as used
+// by the "registry" block's url attribute).
+func registryHost(rawURL string) (string, error) {
+	if !strings.Contains(rawURL, "://") {
+		return rawURL, nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	return u.Host, nil
+}
+
+// parseDockerConfigJSON extracts per-host basic auth credentials from a
+// dockerconfigjson document.
+func parseDockerConfigJSON(raw string) (map[string]registryCredential, error) {
+	var cfg dockerConfigJSON
+	if err := json.Unmarshal([]byte(raw), &cfg); err != nil {
+		return nil, err
+	}
+
+	creds := map[string]registryCredential{}
+	for host, auth := range cfg.Auths {
+		username, password := auth.Username, auth.Password
+		if username == "" && password == "" && auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("could not decode auth for host %q: %v", host, err)
+			}
+			parts := splitAuth(string(decoded))
+			username, password = parts[0], parts[1]
+		}
+		key, err := registryHost(host)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse registry host %q: %v", host, err)
+		}
+		creds[key] = registryCredential{Username: username, Password: password}
+	}
+
+	return creds, nil
+}
+
+// splitAuth splits a decoded "user:password" auth string in two, tolerating
+// a missing password.
+func splitAuth(s string) [2]string {
+	for i := 0; i < len(s); i++ {
+		if s[i] == ':' {
+			return [2]string{s[:i], s[i+1:]}
+		}
+	}
+	return [2]string{s, ""}
+}
+
+// registryLoginCache tracks which OCI registry hosts the provider has
+// already logged into, keyed by host. It lives on Meta instead of a
+// package-level global so that it is scoped to a single provider instance
+// and safe to use from multiple goroutines.
+type registryLoginCache struct {
+	sync.Mutex
+	credentials map[string]registryCredential
+	loggedIn    map[string]bool
+}
+
+func newRegistryLoginCache(credentials map[string]registryCredential) *registryLoginCache {
+	return &registryLoginCache{
+		credentials: credentials,
+		loggedIn:    map[string]bool{},
+	}
+}
+
+// credentialsFor returns the credentials configured for host via the
+// provider's "registry" blocks, if any.
+func (c *registryLoginCache) credentialsFor(host string) (registryCredential, bool) {
+	c.Lock()
+	defer c.Unlock()
+	cred, ok := c.credentials[host]
+	return cred, ok
+}
+
+// isLoggedIn reports whether host has already been logged into.
+func (c *registryLoginCache) isLoggedIn(host string) bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.loggedIn[host]
+}
+
+// markLoggedIn records that host has been logged into.
+func (c *registryLoginCache) markLoggedIn(host string) {
+	c.Lock()
+	defer c.Unlock()
+	c.loggedIn[host] = true
+}
+
+// Invalidate clears the cached login for host, forcing the next
+// OCIRegistryLogin call to log in again. The credentials are not reloaded:
+// those from the provider's "registry" blocks stay as they were read when
+// the provider was configured.
+func (c *registryLoginCache) Invalidate(host string) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.loggedIn, host)
+}
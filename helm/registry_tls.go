@@ -0,0 +1,122 @@
+package helm
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// materializePEMFile returns a filesystem path to s: if s already looks
+// like a path it is returned unchanged, otherwise s is treated as inline
+// PEM data and written to a temporary file, since Helm's chart downloader
+// (action.ChartPathOptions.CaFile/CertFile/KeyFile) only accepts paths.
+// created reports whether a temporary file was written, so the caller knows
+// whether it owns the path and must remove it once done.
+func materializePEMFile(s string) (path string, created bool, err error) {
+	if !strings.HasPrefix(strings.TrimSpace(s), "-----BEGIN") {
+		return s, false, nil
+	}
+
+	f, err := os.CreateTemp("", "terraform-provider-helm-*.pem")
+	if err != nil {
+		return "", false, fmt.Errorf("could not create temporary PEM file: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(s); err != nil {
+		return "", false, fmt.Errorf("could not write temporary PEM file: %v", err)
+	}
+
+	return f.Name(), true, nil
+}
+
+// registryTLSOptions holds the TLS material used when talking to an OCI
+// registry or a plain HTTPS chart repository. It mirrors the way
+// cluster_ca_certificate is handled for the Kubernetes connection: the CA
+// can be supplied either as an inline PEM string or as a path to a file on
+// disk.
+type registryTLSOptions struct {
+	CAFile      string
+	CertFile    string
+	KeyFile     string
+	InsecureTLS bool
+}
+
+// getRegistryTLSOptions reads the repository_ca_file, repository_cert_file,
+// repository_key_file and repository_insecure_tls attributes off d.
+func getRegistryTLSOptions(d dataGetter) registryTLSOptions {
+	var opts registryTLSOptions
+
+	if v, ok := d.GetOk("repository_ca_file"); ok {
+		opts.CAFile = v.(string)
+	}
+	if v, ok := d.GetOk("repository_cert_file"); ok {
+		opts.CertFile = v.(string)
+	}
+	if v, ok := d.GetOk("repository_key_file"); ok {
+		opts.KeyFile = v.(string)
+	}
+	if v, ok := d.GetOkExists("repository_insecure_tls"); ok {
+		opts.InsecureTLS = v.(bool)
+	}
+
+	return opts
+}
+
+// Empty returns true if none of the TLS options have been set, in which
+// case the default transport should be used.
+func (o registryTLSOptions) Empty() bool {
+	return o.CAFile == "" && o.CertFile == "" && o.KeyFile == "" && !o.InsecureTLS
+}
+
+// tlsConfig builds a *tls.Config from the given options. CAFile,
+// CertFile and KeyFile may each be either a PEM-encoded string or a path to
+// a file containing one.
+func (o registryTLSOptions) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: o.InsecureTLS,
+	}
+
+	if o.CAFile != "" {
+		ca, err := pemOrFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load repository_ca_file: %v", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("failed to parse repository_ca_file as PEM")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if o.CertFile != "" || o.KeyFile != "" {
+		cert, err := pemOrFile(o.CertFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load repository_cert_file: %v", err)
+		}
+		key, err := pemOrFile(o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load repository_key_file: %v", err)
+		}
+
+		keyPair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("could not load repository client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{keyPair}
+	}
+
+	return tlsConfig, nil
+}
+
+// pemOrFile returns s as-is if it looks like inline PEM data, otherwise it
+// treats s as a path and reads the file from disk.
+func pemOrFile(s string) ([]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(s), "-----BEGIN") {
+		return []byte(s), nil
+	}
+	return os.ReadFile(s)
+}
@@ -0,0 +1,165 @@
+package helm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"helm.sh/helm/v3/pkg/plugin"
+	"helm.sh/helm/v3/pkg/plugin/installer"
+)
+
+// pluginInstallMu serializes plugin installs/updates across resources. The
+// installer package resolves its target directory from the HELM_PLUGINS
+// environment variable (the same variable m.Settings.PluginsDirectory is
+// seeded from), so installPlugin/updatePlugin below pin it to
+// m.Settings.PluginsDirectory for the duration of the call; the mutex keeps
+// concurrent resources from stepping on each other's HELM_PLUGINS value.
+var pluginInstallMu sync.Mutex
+
+// withPluginsDirectory points the installer package at dir for the duration
+// of fn by pinning HELM_PLUGINS, restoring the previous value afterwards.
+func withPluginsDirectory(dir string, fn func() error) error {
+	pluginInstallMu.Lock()
+	defer pluginInstallMu.Unlock()
+
+	prev, hadPrev := os.LookupEnv("HELM_PLUGINS")
+	if err := os.Setenv("HELM_PLUGINS", dir); err != nil {
+		return fmt.Errorf("could not set HELM_PLUGINS: %v", err)
+	}
+	defer func() {
+		if hadPrev {
+			os.Setenv("HELM_PLUGINS", prev)
+		} else {
+			os.Unsetenv("HELM_PLUGINS")
+		}
+	}()
+
+	return fn()
+}
+
+// resourcePlugin manages the installation of a Helm plugin into the
+// provider's plugins_path, turning plugins_path from a passive setting into
+// a real extension point that post_renderer/values_decryptor on
+// helm_release can refer to by name.
+func resourcePlugin() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourcePluginCreate,
+		ReadContext:   resourcePluginRead,
+		UpdateContext: resourcePluginUpdate,
+		DeleteContext: resourcePluginDelete,
+
+		Description: "Installs a Helm plugin so it can be referenced by name from other resources.",
+
+		Schema: map[string]*schema.Schema{
+			"source": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The git URL or local path to install the plugin from.",
+			},
+			"version": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The version constraint to install, for git sources. Defaults to the latest tag.",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The name the plugin was installed under, as declared in its plugin.yaml.",
+			},
+			"path": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The path the plugin was installed to.",
+			},
+		},
+	}
+}
+
+func resourcePluginCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	m := meta.(*Meta)
+
+	var i installer.Installer
+	err := withPluginsDirectory(m.Settings.PluginsDirectory, func() error {
+		var err error
+		i, err = installer.NewForSource(d.Get("source").(string), d.Get("version").(string))
+		if err != nil {
+			return fmt.Errorf("could not resolve plugin installer: %v", err)
+		}
+		if err := installer.Install(i); err != nil {
+			return fmt.Errorf("could not install plugin: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	p, err := plugin.LoadDir(i.Path())
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("could not load installed plugin: %v", err))
+	}
+
+	d.SetId(p.Metadata.Name)
+	return resourcePluginRead(ctx, d, meta)
+}
+
+func resourcePluginRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	m := meta.(*Meta)
+
+	plugins, err := plugin.FindPlugins(m.Settings.PluginsDirectory)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("could not list installed plugins: %v", err))
+	}
+
+	for _, p := range plugins {
+		if p.Metadata.Name == d.Id() {
+			d.Set("name", p.Metadata.Name)
+			d.Set("path", p.Dir)
+			return nil
+		}
+	}
+
+	debug("[INFO] Plugin %q no longer installed, removing from state", d.Id())
+	d.SetId("")
+	return nil
+}
+
+func resourcePluginUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	m := meta.(*Meta)
+
+	err := withPluginsDirectory(m.Settings.PluginsDirectory, func() error {
+		i, err := installer.NewForSource(d.Get("source").(string), d.Get("version").(string))
+		if err != nil {
+			return fmt.Errorf("could not resolve plugin installer: %v", err)
+		}
+		if err := installer.Update(i); err != nil {
+			return fmt.Errorf("could not update plugin: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourcePluginRead(ctx, d, meta)
+}
+
+func resourcePluginDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	path := d.Get("path").(string)
+	if path == "" {
+		return nil
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		return diag.FromErr(fmt.Errorf("could not remove plugin %q: %v", d.Id(), err))
+	}
+
+	d.SetId("")
+	return nil
+}
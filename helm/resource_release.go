@@ -0,0 +1,368 @@
+package helm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// releaseSchema returns the schema shared between the helm_release resource
+// and the helm_template data source: what chart to install and how to
+// reach its repository or OCI registry.
+func releaseSchema(isDataSource bool) map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Required:    true,
+			ForceNew:    true,
+			Description: "Release name.",
+		},
+		"repository": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Repository URL where to locate the requested chart.",
+		},
+		"chart": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Chart name to be installed.",
+		},
+		"version": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Specify the exact chart version to install.",
+		},
+		"namespace": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			ForceNew:    true,
+			Description: "The namespace to install the release into.",
+		},
+		"repository_username": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Username for HTTP basic auth against the chart repository or OCI registry.",
+		},
+		"repository_password": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Sensitive:   true,
+			Description: "Password for HTTP basic auth against the chart repository or OCI registry.",
+		},
+		"repository_ca_file": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "PEM-encoded root certificates bundle, or a path to a file containing one, used to verify the chart repository or OCI registry.",
+		},
+		"repository_cert_file": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "PEM-encoded client certificate, or a path to a file containing one, used to authenticate against the chart repository or OCI registry.",
+		},
+		"repository_key_file": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "PEM-encoded client certificate key, or a path to a file containing one, used to authenticate against the chart repository or OCI registry.",
+		},
+		"repository_insecure_tls": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: "Whether to skip TLS verification when pulling the chart from its repository or OCI registry.",
+		},
+		"values": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "List of values in raw YAML to pass to helm, later values take precedence.",
+		},
+	}
+
+	if !isDataSource {
+		s["post_renderer"] = &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Name of an installed helm_plugin to pipe the rendered manifest through before it is applied.",
+		}
+		s["values_decryptor"] = &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Name of an installed helm_plugin (e.g. for SOPS) to pipe each values entry through before it is merged.",
+		}
+	}
+
+	return s
+}
+
+func resourceRelease() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceReleaseCreate,
+		ReadContext:   resourceReleaseRead,
+		UpdateContext: resourceReleaseUpdate,
+		DeleteContext: resourceReleaseDelete,
+		Schema:        releaseSchema(false),
+	}
+}
+
+// buildChartPathOptions resolves everything needed to locate and fetch the
+// chart: repository URL, auth, and the TLS material used both for OCI
+// registries (via registry.NewClient, see OCIRegistryLogin) and for plain
+// HTTPS chart repositories (via the ChartDownloader that LocateChart uses).
+//
+// Inline PEM values are materialized to temporary files, since
+// ChartPathOptions only accepts paths. The returned cleanup func removes
+// whichever of those temp files were actually created; callers should defer
+// it once LocateChart has resolved the chart and no longer needs them.
+func buildChartPathOptions(d dataGetter) (cpo *action.ChartPathOptions, cleanup func(), err error) {
+	var tempFiles []string
+	cleanup = func() {
+		for _, f := range tempFiles {
+			os.Remove(f)
+		}
+	}
+
+	cpo = &action.ChartPathOptions{
+		RepoURL:  d.Get("repository").(string),
+		Username: d.Get("repository_username").(string),
+		Password: d.Get("repository_password").(string),
+		Version:  d.Get("version").(string),
+	}
+
+	if v, ok := d.GetOkExists("repository_insecure_tls"); ok {
+		cpo.InsecureSkipTLSverify = v.(bool)
+	}
+
+	tlsOpts := getRegistryTLSOptions(d)
+
+	if tlsOpts.CAFile != "" {
+		path, created, err := materializePEMFile(tlsOpts.CAFile)
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("could not materialize repository_ca_file: %v", err)
+		}
+		if created {
+			tempFiles = append(tempFiles, path)
+		}
+		cpo.CaFile = path
+	}
+	if tlsOpts.CertFile != "" {
+		path, created, err := materializePEMFile(tlsOpts.CertFile)
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("could not materialize repository_cert_file: %v", err)
+		}
+		if created {
+			tempFiles = append(tempFiles, path)
+		}
+		cpo.CertFile = path
+	}
+	if tlsOpts.KeyFile != "" {
+		path, created, err := materializePEMFile(tlsOpts.KeyFile)
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("could not materialize repository_key_file: %v", err)
+		}
+		if created {
+			tempFiles = append(tempFiles, path)
+		}
+		cpo.KeyFile = path
+	}
+
+	return cpo, cleanup, nil
+}
+
+// resolveReleaseValues merges the "values" entries in order, running each
+// one through the values_decryptor plugin first if one is configured.
+func resolveReleaseValues(m *Meta, d dataGetter) (map[string]interface{}, error) {
+	merged := map[string]interface{}{}
+
+	raw, ok := d.GetOk("values")
+	if !ok {
+		return merged, nil
+	}
+
+	decryptor, hasDecryptor := d.GetOk("values_decryptor")
+
+	for _, v := range raw.([]interface{}) {
+		content := []byte(v.(string))
+
+		if hasDecryptor {
+			decrypted, err := RunPlugin(m, decryptor.(string), content)
+			if err != nil {
+				return nil, fmt.Errorf("could not run values_decryptor: %v", err)
+			}
+			content = decrypted
+		}
+
+		current := map[string]interface{}{}
+		if err := yaml.Unmarshal(content, &current); err != nil {
+			return nil, fmt.Errorf("could not parse values: %v", err)
+		}
+		merged = chartutil.CoalesceTables(current, merged)
+	}
+
+	return merged, nil
+}
+
+// pluginPostRenderer adapts an installed Helm plugin to the
+// postrender.PostRenderer interface so that post_renderer can refer to a
+// plugin by name.
+type pluginPostRenderer struct {
+	m    *Meta
+	name string
+}
+
+func (p *pluginPostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	out, err := RunPlugin(p.m, p.name, renderedManifests.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewBuffer(out), nil
+}
+
+func resourceReleaseCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	m := meta.(*Meta)
+	namespace := d.Get("namespace").(string)
+
+	actionConfig, err := m.GetHelmConfiguration(d, namespace)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := OCIRegistryLogin(actionConfig, d, m); err != nil {
+		return diag.FromErr(err)
+	}
+
+	cpo, cleanup, err := buildChartPathOptions(d)
+	defer cleanup()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	chartPath, err := cpo.LocateChart(d.Get("chart").(string), m.Settings)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("could not locate chart: %v", err))
+	}
+
+	chrt, err := loader.Load(chartPath)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("could not load chart: %v", err))
+	}
+
+	vals, err := resolveReleaseValues(m, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	install := action.NewInstall(actionConfig)
+	install.ChartPathOptions = *cpo
+	install.Namespace = namespace
+	install.ReleaseName = d.Get("name").(string)
+
+	if name, ok := d.GetOk("post_renderer"); ok {
+		install.PostRenderer = &pluginPostRenderer{m: m, name: name.(string)}
+	}
+
+	rel, err := install.Run(chrt, vals)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("could not install release: %v", err))
+	}
+
+	d.SetId(rel.Name)
+	return resourceReleaseRead(ctx, d, meta)
+}
+
+func resourceReleaseRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	m := meta.(*Meta)
+	namespace := d.Get("namespace").(string)
+
+	actionConfig, err := m.GetHelmConfiguration(d, namespace)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	get := action.NewGet(actionConfig)
+	rel, err := get.Run(d.Id())
+	if err != nil {
+		debug("[INFO] Release %q no longer exists, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("name", rel.Name)
+	d.Set("namespace", rel.Namespace)
+	d.Set("version", rel.Chart.Metadata.Version)
+	return nil
+}
+
+func resourceReleaseUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	m := meta.(*Meta)
+	namespace := d.Get("namespace").(string)
+
+	actionConfig, err := m.GetHelmConfiguration(d, namespace)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := OCIRegistryLogin(actionConfig, d, m); err != nil {
+		return diag.FromErr(err)
+	}
+
+	cpo, cleanup, err := buildChartPathOptions(d)
+	defer cleanup()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	chartPath, err := cpo.LocateChart(d.Get("chart").(string), m.Settings)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("could not locate chart: %v", err))
+	}
+
+	chrt, err := loader.Load(chartPath)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("could not load chart: %v", err))
+	}
+
+	vals, err := resolveReleaseValues(m, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	upgrade := action.NewUpgrade(actionConfig)
+	upgrade.ChartPathOptions = *cpo
+	upgrade.Namespace = namespace
+
+	if name, ok := d.GetOk("post_renderer"); ok {
+		upgrade.PostRenderer = &pluginPostRenderer{m: m, name: name.(string)}
+	}
+
+	if _, err := upgrade.Run(d.Id(), chrt, vals); err != nil {
+		return diag.FromErr(fmt.Errorf("could not upgrade release: %v", err))
+	}
+
+	return resourceReleaseRead(ctx, d, meta)
+}
+
+func resourceReleaseDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	m := meta.(*Meta)
+	namespace := d.Get("namespace").(string)
+
+	actionConfig, err := m.GetHelmConfiguration(d, namespace)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	uninstall := action.NewUninstall(actionConfig)
+	if _, err := uninstall.Run(d.Id()); err != nil {
+		return diag.FromErr(fmt.Errorf("could not uninstall release: %v", err))
+	}
+
+	d.SetId("")
+	return nil
+}